@@ -0,0 +1,73 @@
+// Package assets downloads a version's asset index and the objects it
+// references into assets/objects/<xx>/<hash>.
+package assets
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+
+	"github.com/MJKWoolnough/minecraft-last/retriever"
+)
+
+// Object is a single entry in an asset index.
+type Object struct {
+	Hash string `json:"hash"`
+	Size int64  `json:"size"`
+}
+
+// Index is the decoded contents of an asset index JSON file.
+type Index struct {
+	Objects map[string]Object `json:"objects"`
+}
+
+// Decode reads an asset index JSON from r.
+func Decode(r io.Reader) (*Index, error) {
+	idx := new(Index)
+	if err := json.NewDecoder(r).Decode(idx); err != nil {
+		return nil, fmt.Errorf("failed to decode asset index: %w", err)
+	}
+
+	return idx, nil
+}
+
+// FetchIndex downloads and decodes the asset index at url.
+func FetchIndex(url string) (*Index, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch asset index: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch asset index: unexpected status %s", resp.Status)
+	}
+
+	return Decode(resp.Body)
+}
+
+// objectURL is the base of Mojang's resource CDN that serves asset
+// objects by hash.
+const objectURL = "https://resources.download.minecraft.net"
+
+// Install downloads every object in idx into assetsDir/objects/<xx>/<hash>.
+func Install(idx *Index, assetsDir string) error {
+	for name, obj := range idx.Objects {
+		if len(obj.Hash) < 2 {
+			return fmt.Errorf("invalid hash for asset %s: %q", name, obj.Hash)
+		}
+
+		prefix := obj.Hash[:2]
+		dest := path.Join(assetsDir, "objects", prefix, obj.Hash)
+		url := objectURL + "/" + prefix + "/" + obj.Hash
+
+		if err := retriever.Get(url, dest, obj.Hash); err != nil {
+			return fmt.Errorf("failed to download asset %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"path"
+
+	"github.com/MJKWoolnough/minecraft-last/assets"
+	"github.com/MJKWoolnough/minecraft-last/java_locator"
+	"github.com/MJKWoolnough/minecraft-last/manifest"
+	"github.com/MJKWoolnough/minecraft-last/retriever"
+	"github.com/MJKWoolnough/minecraft-last/rule"
+	"github.com/MJKWoolnough/minecraft-last/version_manifest"
+)
+
+// installCmd downloads everything needed to run a version of Minecraft:
+// its version JSON, client jar, libraries and natives, asset index and
+// objects, and the Java runtime it requires.
+func installCmd(args []string) error {
+	fs := flag.NewFlagSet("install", flag.ExitOnError)
+
+	usr, err := user.Current()
+	if err != nil {
+		return fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	minecraftDir := fs.String("minecraft", path.Join(usr.HomeDir, ".minecraft"), "Path to minecraft directory")
+	logFlags := addLogFlags(fs)
+	fs.Parse(args)
+
+	logger, closeLog, err := logFlags.logger()
+	if err != nil {
+		return err
+	}
+
+	defer closeLog()
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: minecraft-last install [flags] <version|release|snapshot|latest-release|latest-snapshot>")
+	}
+
+	manifestData, err := version_manifest.Fetch()
+	if err != nil {
+		return err
+	}
+
+	entry, err := manifestData.Resolve(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	logger.Info("installing version", "id", entry.ID, "type", entry.Type)
+
+	versionDir := path.Join(*minecraftDir, versionsDir, entry.ID)
+	versionJSON := path.Join(versionDir, entry.ID+jsonExt)
+
+	if err := retriever.Get(entry.URL, versionJSON, entry.SHA1); err != nil {
+		return fmt.Errorf("failed to download version %s: %w", entry.ID, err)
+	}
+
+	f, err := os.Open(versionJSON)
+	if err != nil {
+		return fmt.Errorf("failed to open version %s: %w", entry.ID, err)
+	}
+
+	version, err := manifest.Decode(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to decode version %s: %w", entry.ID, err)
+	}
+
+	clientJar := path.Join(versionDir, entry.ID+jarExt)
+	if err := retriever.Get(version.Downloads.Client.URL, clientJar, version.Downloads.Client.SHA1); err != nil {
+		return fmt.Errorf("failed to download client jar for %s: %w", entry.ID, err)
+	}
+
+	if err := installLibraries(*minecraftDir, version.Libraries); err != nil {
+		return err
+	}
+
+	if err := installAssets(*minecraftDir, version.AssetIndex); err != nil {
+		return err
+	}
+
+	if err := installJavaRuntime(*minecraftDir, version.JavaVersion); err != nil {
+		return err
+	}
+
+	logger.Info("installed version", "id", entry.ID)
+
+	return nil
+}
+
+func installLibraries(minecraftDir string, libraries []manifest.Library) error {
+	env := rule.CurrentEnv(nil)
+
+	for _, library := range libraries {
+		if !library.Allowed(env) {
+			continue
+		}
+
+		if artifact := library.Downloads.Artifact; artifact != nil {
+			dest := path.Join(minecraftDir, libraryDir, artifact.Path)
+			if err := retriever.Get(artifact.URL, dest, artifact.SHA1); err != nil {
+				return fmt.Errorf("failed to download library %s: %w", library.Name, err)
+			}
+		}
+
+		if native := library.NativeClassifier(env); native != nil {
+			dest := path.Join(minecraftDir, libraryDir, native.Path)
+			if err := retriever.Get(native.URL, dest, native.SHA1); err != nil {
+				return fmt.Errorf("failed to download native library %s: %w", library.Name, err)
+			}
+
+			if err := os.WriteFile(dest+sigExt, []byte(native.SHA1), 0644); err != nil {
+				return fmt.Errorf("failed to write native library signature for %s: %w", library.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func installAssets(minecraftDir string, ref manifest.AssetIndexRef) error {
+	indexPath := path.Join(minecraftDir, "assets", "indexes", ref.ID+jsonExt)
+	if err := retriever.Get(ref.URL, indexPath, ref.SHA1); err != nil {
+		return fmt.Errorf("failed to download asset index %s: %w", ref.ID, err)
+	}
+
+	f, err := os.Open(indexPath)
+	if err != nil {
+		return fmt.Errorf("failed to open asset index %s: %w", ref.ID, err)
+	}
+
+	index, err := assets.Decode(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to decode asset index %s: %w", ref.ID, err)
+	}
+
+	if err := assets.Install(index, path.Join(minecraftDir, "assets")); err != nil {
+		return fmt.Errorf("failed to install assets for index %s: %w", ref.ID, err)
+	}
+
+	return nil
+}
+
+func installJavaRuntime(minecraftDir string, jv manifest.JavaVersion) error {
+	if jv.Component == "" {
+		return nil
+	}
+
+	m, err := java_locator.Fetch()
+	if err != nil {
+		return fmt.Errorf("failed to fetch java-runtime manifest: %w", err)
+	}
+
+	if _, err := java_locator.Install(m, jv.Component, path.Join(minecraftDir, "runtime")); err != nil {
+		return fmt.Errorf("failed to install java runtime %s: %w", jv.Component, err)
+	}
+
+	return nil
+}
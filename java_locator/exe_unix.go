@@ -0,0 +1,5 @@
+//go:build !windows
+
+package java_locator
+
+const javaExecutable = "java"
@@ -0,0 +1,231 @@
+// Package java_locator resolves the Java runtime used to launch a
+// version: it installs Mojang's per-version runtime component under
+// runtime/<component>/<platform>/, and falls back to locating a system
+// JDK/JRE when a version's required component isn't installed and
+// can't be fetched.
+package java_locator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"runtime"
+	"strconv"
+
+	"github.com/MJKWoolnough/minecraft-last/retriever"
+)
+
+// ManifestURL is the location of Mojang's java-runtime component
+// manifest, listing every runtime component's available builds per
+// platform.
+const ManifestURL = "https://launchermeta.mojang.com/v1/products/java-runtime/2ec0cc96c44e5a76b9c8b7c39df7210883d12871/all.json"
+
+// Download describes a single downloadable form of a runtime file.
+type Download struct {
+	SHA1 string `json:"sha1"`
+	Size int64  `json:"size"`
+	URL  string `json:"url"`
+}
+
+// File is a single entry of a runtime component's file listing: a
+// regular file with a raw download, a directory, or a symlink.
+type File struct {
+	Type       string              `json:"type"`
+	Executable bool                `json:"executable"`
+	Target     string              `json:"target"`
+	Downloads  map[string]Download `json:"downloads"`
+}
+
+// ComponentManifest is the decoded manifest referenced by a single
+// available build of a runtime component.
+type ComponentManifest struct {
+	Files map[string]File `json:"files"`
+}
+
+// buildRef points at a single available build of a runtime component
+// for one platform.
+type buildRef struct {
+	Manifest Download `json:"manifest"`
+}
+
+// Manifest is the decoded java-runtime manifest: available runtime
+// component builds, keyed by platform then component name.
+type Manifest map[string]map[string][]buildRef
+
+// Fetch downloads and decodes the java-runtime manifest from
+// ManifestURL.
+func Fetch() (Manifest, error) {
+	resp, err := http.Get(ManifestURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch java-runtime manifest: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch java-runtime manifest: unexpected status %s", resp.Status)
+	}
+
+	m := make(Manifest)
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, fmt.Errorf("failed to decode java-runtime manifest: %w", err)
+	}
+
+	return m, nil
+}
+
+// PlatformName reports the running platform using the keys Mojang's
+// java-runtime manifest uses: "linux", "linux-i386", "mac-os",
+// "mac-os-arm64", "windows-x64" or "windows-x86".
+func PlatformName() string {
+	switch runtime.GOOS {
+	case "linux":
+		if runtime.GOARCH == "386" {
+			return "linux-i386"
+		}
+
+		return "linux"
+	case "darwin":
+		if runtime.GOARCH == "arm64" {
+			return "mac-os-arm64"
+		}
+
+		return "mac-os"
+	case "windows":
+		if runtime.GOARCH == "386" {
+			return "windows-x86"
+		}
+
+		return "windows-x64"
+	default:
+		return runtime.GOOS
+	}
+}
+
+// JavaPath returns the path to the java executable within a runtime
+// directory, whether that's one Install materialized or a system
+// JDK/JRE's home directory.
+func JavaPath(home string) string {
+	return path.Join(home, "bin", javaExecutable)
+}
+
+// Install downloads component for the running platform from m into
+// runtimeDir/<component>/<platform>/, returning the resulting java
+// executable's path.
+func Install(m Manifest, component, runtimeDir string) (string, error) {
+	builds := m[PlatformName()][component]
+	if len(builds) == 0 {
+		return "", fmt.Errorf("no %s runtime available for %s", component, PlatformName())
+	}
+
+	resp, err := http.Get(builds[0].Manifest.URL)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s runtime manifest: %w", component, err)
+	}
+
+	defer resp.Body.Close()
+
+	cm := new(ComponentManifest)
+	if err := json.NewDecoder(resp.Body).Decode(cm); err != nil {
+		return "", fmt.Errorf("failed to decode %s runtime manifest: %w", component, err)
+	}
+
+	home := path.Join(runtimeDir, component, PlatformName())
+
+	for name, file := range cm.Files {
+		dest := path.Join(home, name)
+
+		switch file.Type {
+		case "directory":
+			if err := os.MkdirAll(dest, 0755); err != nil {
+				return "", fmt.Errorf("failed to create %s: %w", dest, err)
+			}
+		case "file":
+			raw := file.Downloads["raw"]
+			if err := retriever.Get(raw.URL, dest, raw.SHA1); err != nil {
+				return "", fmt.Errorf("failed to download %s: %w", name, err)
+			}
+
+			if file.Executable {
+				os.Chmod(dest, 0755)
+			}
+		case "link":
+			if err := os.MkdirAll(path.Dir(dest), 0755); err != nil {
+				return "", fmt.Errorf("failed to create %s: %w", dest, err)
+			}
+
+			os.Remove(dest)
+
+			if err := os.Symlink(file.Target, dest); err != nil {
+				return "", fmt.Errorf("failed to link %s: %w", dest, err)
+			}
+		}
+	}
+
+	return JavaPath(home), nil
+}
+
+// Locate searches for a system Java installation: $JAVA_HOME first,
+// then well-known per-OS install locations.
+func Locate() (string, error) {
+	if home := os.Getenv("JAVA_HOME"); home != "" {
+		if p := JavaPath(home); IsExecutableFile(p) {
+			return p, nil
+		}
+	}
+
+	for _, home := range systemJavaHomes() {
+		if p := JavaPath(home); IsExecutableFile(p) {
+			return p, nil
+		}
+	}
+
+	if p, err := exec.LookPath(javaExecutable); err == nil {
+		return p, nil
+	}
+
+	return "", fmt.Errorf("no installed or system java runtime found")
+}
+
+// IsExecutableFile reports whether p exists and is a regular file.
+func IsExecutableFile(p string) bool {
+	info, err := os.Stat(p)
+
+	return err == nil && info.Mode().IsRegular()
+}
+
+// versionOutput matches the version number out of `java -version`'s
+// output, e.g. `java version "17.0.1"` or `openjdk version "1.8.0_292"`.
+var versionOutput = regexp.MustCompile(`version "(\d+)(?:\.(\d+))?`)
+
+// MajorVersion runs the java executable at p with -version and parses
+// its reported major version, normalising the legacy 1.x scheme (Java
+// 8 reports "1.8.0_...") to the modern single-number one.
+func MajorVersion(p string) (int, error) {
+	out, err := exec.Command(p, "-version").CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("failed to run %s -version: %w", p, err)
+	}
+
+	m := versionOutput.FindSubmatch(out)
+	if m == nil {
+		return 0, fmt.Errorf("failed to parse java version from: %s", out)
+	}
+
+	major, err := strconv.Atoi(string(m[1]))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse java version from: %s", out)
+	}
+
+	if major == 1 && m[2] != nil {
+		if major, err = strconv.Atoi(string(m[2])); err != nil {
+			return 0, fmt.Errorf("failed to parse java version from: %s", out)
+		}
+	}
+
+	return major, nil
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+
+package java_locator
+
+import (
+	"path/filepath"
+	"runtime"
+)
+
+// systemJavaHomes globs the well-known per-OS install roots for a
+// system Java: /usr/lib/jvm/* on Linux, and the Contents/Home of each
+// installed JVM bundle on macOS.
+func systemJavaHomes() []string {
+	var pattern string
+
+	switch runtime.GOOS {
+	case "darwin":
+		pattern = "/Library/Java/JavaVirtualMachines/*/Contents/Home"
+	default:
+		pattern = "/usr/lib/jvm/*"
+	}
+
+	homes, _ := filepath.Glob(pattern)
+
+	return homes
+}
@@ -0,0 +1,82 @@
+//go:build windows
+
+package java_locator
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// systemJavaHomes enumerates HKLM\SOFTWARE\JavaSoft\JDK and \JRE,
+// returning the JavaHome value of every installed version found there.
+func systemJavaHomes() []string {
+	var homes []string
+
+	for _, key := range []string{`SOFTWARE\JavaSoft\JDK`, `SOFTWARE\JavaSoft\JRE`} {
+		homes = append(homes, javaHomesUnder(key)...)
+	}
+
+	return homes
+}
+
+func javaHomesUnder(subKey string) []string {
+	keyPtr, err := syscall.UTF16PtrFromString(subKey)
+	if err != nil {
+		return nil
+	}
+
+	var key syscall.Handle
+	if syscall.RegOpenKeyEx(syscall.HKEY_LOCAL_MACHINE, keyPtr, 0, syscall.KEY_READ, &key) != nil {
+		return nil
+	}
+
+	defer syscall.RegCloseKey(key)
+
+	var homes []string
+
+	for i := uint32(0); ; i++ {
+		nameBuf := make([]uint16, 256)
+		nameLen := uint32(len(nameBuf))
+
+		if syscall.RegEnumKeyEx(key, i, &nameBuf[0], &nameLen, nil, nil, nil, nil) != nil {
+			break
+		}
+
+		if home, ok := javaHomeValue(key, syscall.UTF16ToString(nameBuf[:nameLen])); ok {
+			homes = append(homes, home)
+		}
+	}
+
+	return homes
+}
+
+func javaHomeValue(parent syscall.Handle, version string) (string, bool) {
+	versionPtr, err := syscall.UTF16PtrFromString(version)
+	if err != nil {
+		return "", false
+	}
+
+	var sub syscall.Handle
+	if syscall.RegOpenKeyEx(parent, versionPtr, 0, syscall.KEY_READ, &sub) != nil {
+		return "", false
+	}
+
+	defer syscall.RegCloseKey(sub)
+
+	namePtr, err := syscall.UTF16PtrFromString("JavaHome")
+	if err != nil {
+		return "", false
+	}
+
+	var typ, size uint32
+	if syscall.RegQueryValueEx(sub, namePtr, nil, &typ, nil, &size) != nil || size == 0 {
+		return "", false
+	}
+
+	buf := make([]uint16, size/2+1)
+	if syscall.RegQueryValueEx(sub, namePtr, nil, &typ, (*byte)(unsafe.Pointer(&buf[0])), &size) != nil {
+		return "", false
+	}
+
+	return syscall.UTF16ToString(buf), true
+}
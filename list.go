@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"path"
+)
+
+// listCmd prints the versions currently installed under the minecraft
+// directory's versions folder.
+func listCmd(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+
+	usr, err := user.Current()
+	if err != nil {
+		return fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	minecraftDir := fs.String("minecraft", path.Join(usr.HomeDir, ".minecraft"), "Path to minecraft directory")
+	logFlags := addLogFlags(fs)
+	fs.Parse(args)
+
+	logger, closeLog, err := logFlags.logger()
+	if err != nil {
+		return err
+	}
+
+	defer closeLog()
+
+	entries, err := os.ReadDir(path.Join(*minecraftDir, versionsDir))
+	if err != nil {
+		return fmt.Errorf("failed to read versions directory: %w", err)
+	}
+
+	logger.Debug("read versions directory", "minecraft", *minecraftDir, "entries", len(entries))
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			fmt.Println(entry.Name())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// logFlags are the logging flags shared by every subcommand: the log
+// level, whether to log as JSON, and an optional file to log to
+// instead of stderr.
+type logFlags struct {
+	level *string
+	json  *bool
+	file  *string
+}
+
+// addLogFlags registers the shared --log-level, --log-json and
+// --log-file flags on fs.
+func addLogFlags(fs *flag.FlagSet) *logFlags {
+	return &logFlags{
+		level: fs.String("log-level", "info", "Log level: debug, info, warn, or error"),
+		json:  fs.Bool("log-json", false, "Log as JSON instead of text"),
+		file:  fs.String("log-file", "", "Write logs to this file instead of stderr"),
+	}
+}
+
+// logger builds the slog.Logger these flags select: a text handler to
+// stderr by default, or a JSON handler when --log-json or --log-file
+// is set. The returned close function must be called once logging is
+// done.
+func (f *logFlags) logger() (*slog.Logger, func() error, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(*f.level)); err != nil {
+		return nil, nil, fmt.Errorf("invalid log level %q: %w", *f.level, err)
+	}
+
+	w := os.Stderr
+	closeFn := func() error { return nil }
+
+	if *f.file != "" {
+		file, err := os.OpenFile(*f.file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log file %s: %w", *f.file, err)
+		}
+
+		w = file
+		closeFn = file.Close
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	if *f.json || *f.file != "" {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
+	}
+
+	return slog.New(handler), closeFn, nil
+}
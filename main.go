@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = "usage: minecraft-last <run|install|list> [flags]\n"
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	cmd, args := os.Args[1], os.Args[2:]
+
+	var err error
+	switch cmd {
+	case "run":
+		err = runCmd(args)
+	case "install":
+		err = installCmd(args)
+	case "list":
+		err = listCmd(args)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n%s", cmd, usage)
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+}
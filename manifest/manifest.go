@@ -0,0 +1,104 @@
+// Package manifest decodes a single version's JSON file (as found at
+// versions/<id>/<id>.json) for the purposes of downloading that version:
+// the client jar, its libraries and natives, and its asset index.
+package manifest
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/MJKWoolnough/minecraft-last/rule"
+)
+
+// Download describes a single downloadable file.
+type Download struct {
+	SHA1 string `json:"sha1"`
+	Size int64  `json:"size"`
+	URL  string `json:"url"`
+}
+
+// Artifact is a Download with the path it should be written to, relative
+// to the libraries directory.
+type Artifact struct {
+	Download
+	Path string `json:"path"`
+}
+
+// Downloads holds the top-level downloadable files of a version: only
+// the client jar is needed for installing.
+type Downloads struct {
+	Client Download `json:"client"`
+}
+
+// LibraryDownloads holds the download locations for a library: the
+// regular artifact and, for libraries that ship native code, one
+// classifier artifact per platform.
+type LibraryDownloads struct {
+	Artifact    *Artifact            `json:"artifact"`
+	Classifiers map[string]*Artifact `json:"classifiers"`
+}
+
+// Library is a single library entry of a version, including its
+// optional natives classifier for the current platform.
+type Library struct {
+	Name      string            `json:"name"`
+	Downloads LibraryDownloads  `json:"downloads"`
+	Natives   map[string]string `json:"natives"`
+	Rules     []rule.Rule       `json:"rules"`
+}
+
+// Allowed reports whether this library should be installed for env,
+// deferring to the same rule evaluation run.go uses so install and run
+// agree on which libraries and natives apply to a given machine.
+func (l Library) Allowed(env rule.Env) bool {
+	return rule.Allowed(l.Rules, env)
+}
+
+// NativeClassifier returns the classifier download for this library's
+// native code on env's platform, if it has one.
+func (l Library) NativeClassifier(env rule.Env) *Artifact {
+	classifier, ok := l.Natives[env.OSName]
+	if !ok {
+		return nil
+	}
+
+	return l.Downloads.Classifiers[classifier]
+}
+
+// AssetIndexRef points at a version's asset index.
+type AssetIndexRef struct {
+	ID        string `json:"id"`
+	SHA1      string `json:"sha1"`
+	Size      int64  `json:"size"`
+	URL       string `json:"url"`
+	TotalSize int64  `json:"totalSize"`
+}
+
+// JavaVersion names the runtime component a version was built to run
+// on, as published by Mojang's java-runtime component manifest.
+type JavaVersion struct {
+	Component    string `json:"component"`
+	MajorVersion int    `json:"majorVersion"`
+}
+
+// Version is the subset of a version JSON needed to install it: where
+// to fetch the client jar, libraries, asset index and Java runtime
+// from.
+type Version struct {
+	ID          string        `json:"id"`
+	Type        string        `json:"type"`
+	Downloads   Downloads     `json:"downloads"`
+	Libraries   []Library     `json:"libraries"`
+	AssetIndex  AssetIndexRef `json:"assetIndex"`
+	JavaVersion JavaVersion   `json:"javaVersion"`
+}
+
+// Decode reads a version JSON from r.
+func Decode(r io.Reader) (*Version, error) {
+	v := new(Version)
+	if err := json.NewDecoder(r).Decode(v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
@@ -0,0 +1,122 @@
+// Package rule evaluates the allow/disallow rule lists attached to
+// libraries and modern arguments in a version manifest. Whether an
+// entry applies depends on the running OS name, version and
+// architecture, plus a set of launcher feature flags such as
+// is_demo_user or has_custom_resolution.
+package rule
+
+import (
+	"regexp"
+	"runtime"
+)
+
+const allow = "allow"
+
+// OS matches a rule's platform conditions against the running machine.
+// Version is compiled as a regular expression against the platform's
+// reported OS version, which is how Mojang manifests express things
+// like the `10.5.\d+` macOS LWJGL exclusion.
+type OS struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Arch    string `json:"arch"`
+}
+
+// Rule is a single allow/disallow entry, as found in a library's or
+// argument's "rules" array.
+type Rule struct {
+	Action   string          `json:"action"`
+	OS       OS              `json:"os"`
+	Features map[string]bool `json:"features"`
+}
+
+// Env describes the platform a rules list is evaluated against: the
+// running OS name, version and architecture, and the feature flags
+// this launch supports.
+type Env struct {
+	OSName    string
+	OSVersion string
+	Arch      string
+	Features  map[string]bool
+}
+
+// CurrentEnv returns the Env for the machine this process is running
+// on, combined with the given feature flags.
+func CurrentEnv(features map[string]bool) Env {
+	return Env{
+		OSName:    osName(),
+		OSVersion: osVersion(),
+		Arch:      arch(),
+		Features:  features,
+	}
+}
+
+// Applies reports whether this rule matches env. An empty os.name,
+// os.version or os.arch matches any value; a non-empty os.version is
+// matched as a regular expression against env.OSVersion. Every key
+// present in Features must have the same value in env.Features.
+func (r Rule) Applies(env Env) bool {
+	if r.OS.Name != "" && r.OS.Name != env.OSName {
+		return false
+	}
+
+	if r.OS.Arch != "" && r.OS.Arch != env.Arch {
+		return false
+	}
+
+	if r.OS.Version != "" {
+		matched, err := regexp.MatchString(r.OS.Version, env.OSVersion)
+		if err != nil || !matched {
+			return false
+		}
+	}
+
+	for feature, want := range r.Features {
+		if env.Features[feature] != want {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Allowed evaluates a rules list in order: an empty list always allows,
+// and each rule that Applies to env overrides the running verdict with
+// its action, so the last matching rule wins.
+func Allowed(rules []Rule, env Env) bool {
+	allowed := len(rules) == 0
+
+	for _, r := range rules {
+		if r.Applies(env) {
+			allowed = r.Action == allow
+		}
+	}
+
+	return allowed
+}
+
+// arch reports the running architecture using the names Mojang
+// manifests use for os.arch: x86, x86_64 or arm64.
+func arch() string {
+	switch runtime.GOARCH {
+	case "386":
+		return "x86"
+	case "amd64":
+		return "x86_64"
+	case "arm64":
+		return "arm64"
+	default:
+		return runtime.GOARCH
+	}
+}
+
+// osName reports the running OS using the names Mojang manifests use
+// for os.name: macOS is reported as "osx".
+func osName() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "osx"
+	default:
+		return runtime.GOOS
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package rule
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// osVersion reports the running kernel version, via `uname -r`, as
+// used by Mojang's macOS-specific rules.
+func osVersion() string {
+	out, err := exec.Command("uname", "-r").Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,32 @@
+//go:build windows
+
+package rule
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+type osVersionInfo struct {
+	osVersionInfoSize uint32
+	majorVersion      uint32
+	minorVersion      uint32
+	buildNumber       uint32
+	platformID        uint32
+	csdVersion        [128]uint16
+}
+
+// osVersion reports the running Windows version as "major.minor.build",
+// queried via ntdll's RtlGetVersion since GetVersionEx is subject to
+// the application-manifest compatibility shims that would otherwise
+// mask it.
+func osVersion() string {
+	ntdll := syscall.NewLazyDLL("ntdll.dll")
+	rtlGetVersion := ntdll.NewProc("RtlGetVersion")
+
+	info := osVersionInfo{osVersionInfoSize: uint32(unsafe.Sizeof(osVersionInfo{}))}
+	rtlGetVersion.Call(uintptr(unsafe.Pointer(&info)))
+
+	return fmt.Sprintf("%d.%d.%d", info.majorVersion, info.minorVersion, info.buildNumber)
+}
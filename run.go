@@ -0,0 +1,636 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"os/user"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/MJKWoolnough/minecraft-last/java_locator"
+	"github.com/MJKWoolnough/minecraft-last/rule"
+)
+
+const (
+	launcherProfiles = "launcher_profiles.json"
+	versionsDir      = "versions"
+	libraryDir       = "libraries"
+	jsonExt          = ".json"
+	jarExt           = ".jar"
+	sigExt           = ".sha"
+	launcherName     = "minecraft-last"
+	launcherVersion  = "1.0"
+)
+
+type Profile struct {
+	ID   string `json:"lastVersionId"`
+	Args string `json:"javaArgs"`
+}
+
+type User struct {
+	Name        string `json:"displayName"`
+	AccessToken string `json:"accessToken"`
+}
+
+type ProfileData struct {
+	Profiles        map[string]Profile `json:"profiles"`
+	SelectedProfile string             `json:"selectedProfile"`
+	Users           map[string]User    `json:"authenticationDatabase"`
+	SelectedUser    string             `json:"selectedUser"`
+}
+
+type Library struct {
+	Name    string              `json:"name"`
+	Rules   []rule.Rule         `json:"rules"`
+	Natives map[string]string   `json:"natives"`
+	Extract map[string][]string `json:"extract"`
+}
+
+// Argument is a single entry of a modern arguments list: either a bare
+// string or a conditional form whose value only applies when its rules
+// match the running platform. Value is always stored as a slice, since
+// the conditional form may itself hold either a string or []string.
+type Argument struct {
+	Rules []rule.Rule
+	Value []string
+}
+
+// UnmarshalJSON accepts either a bare JSON string or the
+// {rules, value} object form, normalising both into Value.
+func (a *Argument) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		a.Value = []string{s}
+		return nil
+	}
+
+	var obj struct {
+		Rules []rule.Rule     `json:"rules"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+
+	a.Rules = obj.Rules
+
+	if err := json.Unmarshal(obj.Value, &s); err == nil {
+		a.Value = []string{s}
+		return nil
+	}
+
+	return json.Unmarshal(obj.Value, &a.Value)
+}
+
+// Arguments holds the modern, post-1.13 game and JVM argument lists,
+// which replace the single minecraftArguments string.
+type Arguments struct {
+	Game []Argument `json:"game"`
+	JVM  []Argument `json:"jvm"`
+}
+
+// JavaVersion names the runtime a version was built to run on, as
+// published by Mojang's java-runtime component manifest.
+type JavaVersion struct {
+	Component    string `json:"component"`
+	MajorVersion int    `json:"majorVersion"`
+}
+
+type LaunchConfig struct {
+	Args         string      `json:"minecraftArguments"`
+	Arguments    *Arguments  `json:"arguments"`
+	Libraries    []Library   `json:"libraries"`
+	Class        string      `json:"mainClass"`
+	InheritsFrom string      `json:"inheritsFrom"`
+	Assets       string      `json:"assets"`
+	Type         string      `json:"type"`
+	JavaVersion  JavaVersion `json:"javaVersion"`
+}
+
+// loadLaunchConfig decodes the version JSON for id and, if it inherits
+// from a parent version (as Forge/Fabric/OptiFine profiles do), loads
+// and merges that parent in turn. It also returns the id of the version
+// whose directory actually holds the client jar, which for an inheriting
+// child with no jar of its own is the nearest ancestor that has one.
+func loadLaunchConfig(minecraftDir, id string) (*LaunchConfig, string, error) {
+	return loadLaunchConfigVisiting(minecraftDir, id, make(map[string]bool))
+}
+
+// loadLaunchConfigVisiting is loadLaunchConfig's recursive implementation.
+// It tracks the ids seen so far so a version JSON chain that inherits
+// from itself, directly or indirectly, fails with an error instead of
+// recursing forever.
+func loadLaunchConfigVisiting(minecraftDir, id string, visited map[string]bool) (*LaunchConfig, string, error) {
+	if visited[id] {
+		return nil, "", fmt.Errorf("inheritsFrom cycle detected at %s", id)
+	}
+
+	visited[id] = true
+
+	versionDir := path.Join(minecraftDir, versionsDir, id)
+
+	f, err := os.Open(path.Join(versionDir, id+jsonExt))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open profile configuration: %w", err)
+	}
+
+	cfg := new(LaunchConfig)
+	err = json.NewDecoder(f).Decode(cfg)
+	f.Close()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode profile configuration: %w", err)
+	}
+
+	jarID := id
+	if _, err := os.Stat(path.Join(versionDir, id+jarExt)); err != nil {
+		jarID = ""
+	}
+
+	if cfg.InheritsFrom != "" {
+		parent, parentJarID, err := loadLaunchConfigVisiting(minecraftDir, cfg.InheritsFrom, visited)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if jarID == "" {
+			jarID = parentJarID
+		}
+
+		cfg = mergeLaunchConfig(parent, cfg)
+	}
+
+	return cfg, jarID, nil
+}
+
+// mergeLaunchConfig combines a parent profile with a child that
+// inherits from it: parent libraries come first, with any child library
+// sharing a group:artifact overriding the parent's version of it, and
+// mainClass/Args are taken from the child when it sets them.
+func mergeLaunchConfig(parent, child *LaunchConfig) *LaunchConfig {
+	merged := &LaunchConfig{
+		Args:        parent.Args,
+		Class:       parent.Class,
+		Assets:      parent.Assets,
+		Type:        parent.Type,
+		JavaVersion: parent.JavaVersion,
+		Arguments:   mergeArguments(parent.Arguments, child.Arguments),
+		Libraries:   append([]Library(nil), parent.Libraries...),
+	}
+
+	if child.Args != "" {
+		merged.Args = child.Args
+	}
+
+	if child.Class != "" {
+		merged.Class = child.Class
+	}
+
+	if child.Assets != "" {
+		merged.Assets = child.Assets
+	}
+
+	if child.Type != "" {
+		merged.Type = child.Type
+	}
+
+	if child.JavaVersion.Component != "" {
+		merged.JavaVersion = child.JavaVersion
+	}
+
+	for _, lib := range child.Libraries {
+		key := libraryKey(lib.Name)
+		replaced := false
+
+		for i, existing := range merged.Libraries {
+			if libraryKey(existing.Name) == key {
+				merged.Libraries[i] = lib
+				replaced = true
+
+				break
+			}
+		}
+
+		if !replaced {
+			merged.Libraries = append(merged.Libraries, lib)
+		}
+	}
+
+	return merged
+}
+
+// libraryKey returns the group:artifact portion of a library name,
+// ignoring its version, so libraries can be matched across a profile
+// and its parent regardless of which version each one pins.
+func libraryKey(name string) string {
+	pieces := strings.SplitN(name, ":", 3)
+	if len(pieces) < 2 {
+		return name
+	}
+
+	return pieces[0] + ":" + pieces[1]
+}
+
+// mergeArguments concatenates a parent and child's modern argument
+// lists. Forge/Fabric/OptiFine version JSONs only list the arguments
+// they add on top of whatever the inherited version already specifies,
+// so the parent's entries always come first.
+func mergeArguments(parent, child *Arguments) *Arguments {
+	if parent == nil {
+		return child
+	}
+
+	if child == nil {
+		return parent
+	}
+
+	return &Arguments{
+		Game: append(append([]Argument(nil), parent.Game...), child.Game...),
+		JVM:  append(append([]Argument(nil), parent.JVM...), child.JVM...),
+	}
+}
+
+// resolveArguments evaluates a modern argument list against env,
+// substituting template variables in the entries that survive.
+func resolveArguments(args []Argument, env rule.Env, replacer *strings.Replacer) []string {
+	out := make([]string, 0, len(args))
+
+	for _, arg := range args {
+		if !rule.Allowed(arg.Rules, env) {
+			continue
+		}
+
+		for _, v := range arg.Value {
+			out = append(out, replacer.Replace(v))
+		}
+	}
+
+	return out
+}
+
+// resolveJava returns the path to the java executable a version
+// should launch with. If the version names a required runtime
+// component that's already installed under minecraftDir's runtime
+// directory it's reused as-is; otherwise the component is fetched and
+// installed, falling back to a system JDK/JRE when that isn't
+// possible (e.g. no network). A system JDK/JRE is rejected if it
+// doesn't match the version's required major version.
+func resolveJava(minecraftDir string, jv JavaVersion) (string, error) {
+	if jv.Component != "" {
+		runtimeDir := path.Join(minecraftDir, "runtime")
+		home := path.Join(runtimeDir, jv.Component, java_locator.PlatformName())
+
+		if p := java_locator.JavaPath(home); java_locator.IsExecutableFile(p) {
+			return p, nil
+		}
+
+		if m, err := java_locator.Fetch(); err == nil {
+			if p, err := java_locator.Install(m, jv.Component, runtimeDir); err == nil {
+				return p, nil
+			}
+		}
+	}
+
+	p, err := java_locator.Locate()
+	if err != nil {
+		return "", err
+	}
+
+	if jv.MajorVersion != 0 {
+		major, err := java_locator.MajorVersion(p)
+		if err != nil {
+			return "", fmt.Errorf("failed to determine java version for %s: %w", p, err)
+		}
+
+		if major != jv.MajorVersion {
+			return "", fmt.Errorf("found java %d at %s, but this version requires java %d", major, p, jv.MajorVersion)
+		}
+	}
+
+	return p, nil
+}
+
+var hex = [...]byte{'0', '1', '2', '3', '4', '5', '6', '7', '8', '9', 'a', 'b', 'c', 'd', 'e', 'f'}
+
+// runCmd launches an already-installed version of Minecraft, resolving
+// the user and profile to use from launcher_profiles.json.
+func runCmd(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+
+	usr, err := user.Current()
+	if err != nil {
+		return fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	minecraftDir := fs.String("minecraft", path.Join(usr.HomeDir, ".minecraft"), "Path to minecraft directory")
+	profile := fs.String("profile", "", "Selected profile to launch")
+	user := fs.String("user", "", "Selected user to launch with")
+	lastProfile := fs.Bool("lastprofile", false, "Launch last used profile")
+	lastUser := fs.Bool("lastuser", false, "Launch with last used user profile")
+	demoUser := fs.Bool("demo", false, "Launch as a demo user, for rules gated on the is_demo_user feature")
+	customResolution := fs.Bool("resolution", false, "Set the has_custom_resolution feature, for rules that depend on it")
+	javaPath := fs.String("java", "", "Path to the java executable to launch with, overriding runtime discovery")
+	logFlags := addLogFlags(fs)
+	fs.Parse(args)
+
+	logger, closeLog, err := logFlags.logger()
+	if err != nil {
+		return err
+	}
+
+	defer closeLog()
+
+	if err := os.Chdir(*minecraftDir); err != nil {
+		return fmt.Errorf("failed to change to minecraft directory: %w", err)
+	}
+
+	f, err := os.Open(path.Join(*minecraftDir, launcherProfiles))
+	if err != nil {
+		return fmt.Errorf("failed to open launcher profiles: %w", err)
+	}
+
+	profileData := new(ProfileData)
+	err = json.NewDecoder(f).Decode(profileData)
+
+	f.Close()
+
+	if err != nil {
+		return fmt.Errorf("failed to decode profiles: %w", err)
+	}
+
+	if *lastUser {
+		*user = profileData.SelectedUser
+	} else {
+		for uuid, u := range profileData.Users {
+			if u.Name == *user {
+				*user = uuid
+				break
+			}
+		}
+	}
+	if _, ok := profileData.Users[*user]; !ok {
+		fmt.Fprint(os.Stderr, "incorrect or no user selected, please choose one of the following: -\n")
+		for uuid, u := range profileData.Users {
+			ext := ""
+			if uuid == profileData.SelectedUser {
+				ext = " (-lastuser)"
+			}
+			fmt.Fprintf(os.Stderr, "	%s%s\n", u.Name, ext)
+		}
+
+		return nil
+	}
+
+	if *lastProfile {
+		*profile = profileData.SelectedProfile
+	}
+	if _, ok := profileData.Profiles[*profile]; !ok {
+		fmt.Fprint(os.Stderr, "incorrect or no profile selected, please choose one of the following: -\n")
+		for p := range profileData.Profiles {
+			ext := ""
+			if p == profileData.SelectedProfile {
+				ext = " (-lastprofile)"
+			}
+			fmt.Fprintf(os.Stderr, "	%s%s\n", p, ext)
+		}
+
+		return nil
+	}
+
+	versionID := profileData.Profiles[*profile].ID
+	versionDir := path.Join(*minecraftDir, versionsDir, versionID)
+
+	launchConfig, jarID, err := loadLaunchConfig(*minecraftDir, versionID)
+	if err != nil {
+		return err
+	}
+
+	if jarID == "" {
+		jarID = versionID
+	}
+
+	env := rule.CurrentEnv(map[string]bool{
+		"is_demo_user":          *demoUser,
+		"has_custom_resolution": *customResolution,
+	})
+
+	nativesDir := path.Join(versionDir, "natives")
+
+	os.Mkdir(nativesDir, 0755)
+
+	libraries := make([]string, 0, len(launchConfig.Libraries))
+
+	hashW := sha1.New()
+
+	for _, library := range launchConfig.Libraries {
+		if !rule.Allowed(library.Rules, env) {
+			continue
+		}
+		pieces := strings.SplitN(library.Name, ":", 2)
+		if len(pieces) != 2 {
+			return fmt.Errorf("unknown library format: %s", library.Name)
+		}
+
+		librarySplit := strings.Split(pieces[1], ":")
+		pathSplit := append(strings.Split(pieces[0], "."), librarySplit...)
+		signature := make([]byte, 40)
+		if library.Natives[env.OSName] != "" {
+			filename := strings.Join(librarySplit, "-") + "-" + library.Natives[env.OSName] + jarExt
+			nativeLib := path.Join(path.Join(*minecraftDir, libraryDir), path.Join(append(pathSplit, filename)...))
+
+			sig, err := os.Open(nativeLib + sigExt)
+			if err != nil {
+				return fmt.Errorf("failed to read native library signature: %w", err)
+			}
+
+			_, err = io.ReadFull(sig, signature)
+			sig.Close()
+			if err != nil {
+				return fmt.Errorf("failure while reading native library signature: %w", err)
+			}
+
+			f, err := os.Open(nativeLib)
+			if err != nil {
+				return fmt.Errorf("failed to open native library for extraction: %w", err)
+			}
+
+			n, err := io.Copy(hashW, f)
+			if err != nil {
+				return fmt.Errorf("failure when reading compressed native library: %w", err)
+			}
+
+			for n, b := range hashW.Sum(nil) {
+				if hex[b>>4] != signature[n<<1] || hex[b&15] != signature[n<<1+1] {
+					return fmt.Errorf("signature verification failed on %s, expecting %s", filename, signature)
+				}
+			}
+
+			hashW.Reset()
+
+			_, err = f.Seek(0, os.SEEK_SET)
+			if err != nil {
+				return fmt.Errorf("failure when seeking in the compressed native library: %w", err)
+			}
+
+			z, err := zip.NewReader(f, n)
+			if err != nil {
+				return fmt.Errorf("failure when opening compressed native library: %w", err)
+			}
+
+			excludes := library.Extract["exclude"]
+		ZipLoop:
+			for _, file := range z.File {
+				for _, exclude := range excludes {
+					if len(file.Name) >= len(exclude) && file.Name[:len(exclude)] == exclude {
+						continue ZipLoop
+					}
+				}
+				df, err := os.Create(path.Join(nativesDir, file.Name))
+				if err != nil {
+					return fmt.Errorf("failed to create decompressed file: %w", err)
+				}
+
+				cf, err := file.Open()
+				if err != nil {
+					return fmt.Errorf("failed to open compressed file: %w", err)
+				}
+
+				_, err = io.Copy(df, cf)
+				if err != nil {
+					return fmt.Errorf("failed to decompress file: %w", err)
+				}
+			}
+			f.Close()
+		} else {
+			filename := strings.Join(librarySplit, "-") + jarExt
+			lPath := path.Join(path.Join(*minecraftDir, libraryDir), path.Join(append(pathSplit, filename)...))
+			libraries = append(libraries, lPath)
+		}
+	}
+
+	libraries = append(libraries, path.Join(*minecraftDir, versionsDir, jarID, jarID+jarExt))
+
+	var args2 []string
+
+	if launchConfig.Arguments != nil {
+		vars := map[string]string{
+			"auth_player_name":  profileData.Users[*user].Name,
+			"auth_uuid":         *user,
+			"auth_access_token": profileData.Users[*user].AccessToken,
+			"auth_xuid":         "",
+			"clientid":          "",
+			"user_type":         "mojang",
+			"version_name":      versionID,
+			"version_type":      launchConfig.Type,
+			"game_directory":    *minecraftDir,
+			"assets_root":       path.Join(*minecraftDir, "assets"),
+			"assets_index_name": launchConfig.Assets,
+			"natives_directory": nativesDir,
+			"launcher_name":     launcherName,
+			"launcher_version":  launcherVersion,
+			"classpath":         strings.Join(libraries, string(os.PathListSeparator)),
+			"user_properties":   "{}",
+		}
+
+		pairs := make([]string, 0, len(vars)*2)
+		for name, value := range vars {
+			pairs = append(pairs, "${"+name+"}", value)
+		}
+		replacer := strings.NewReplacer(pairs...)
+
+		args2 = append(args2, resolveArguments(launchConfig.Arguments.JVM, env, replacer)...)
+		args2 = append(args2, launchConfig.Class)
+		args2 = append(args2, resolveArguments(launchConfig.Arguments.Game, env, replacer)...)
+	} else {
+		args2 = append(args2,
+			"-Xmx1G",
+			"-XX:+UseConcMarkSweepGC",
+			"-XX:+CMSIncrementalMode",
+			"-XX:-UseAdaptiveSizePolicy",
+			"-Xmn128M",
+			"-Djava.library.path="+nativesDir,
+			"-cp",
+			strings.Join(libraries, string(os.PathListSeparator)),
+			launchConfig.Class,
+		)
+
+		ma := strings.Split(launchConfig.Args, " ")
+		for i, arg := range ma {
+			switch arg {
+			case "${auth_player_name}":
+				ma[i] = profileData.Users[*user].Name
+			case "${auth_session}":
+				ma[i] = "token:" + profileData.Users[*user].AccessToken + ":" + *user
+			case "${version_name}":
+				ma[i] = profileData.Profiles[*profile].ID
+			case "${game_directory}":
+				ma[i] = *minecraftDir
+			case "${game_assets}":
+				ma[i] = path.Join(*minecraftDir, "assets", "virtual", "legacy")
+			}
+		}
+		args2 = append(args2, ma...)
+	}
+
+	java := *javaPath
+	if java == "" {
+		java, err = resolveJava(*minecraftDir, launchConfig.JavaVersion)
+		if err != nil {
+			return fmt.Errorf("failed to resolve java runtime: %w", err)
+		}
+	}
+
+	cmd := exec.Command(java, args2...)
+	logger.Info("launching minecraft", "profile", *profile, "user", profileData.Users[*user].Name, "java", java)
+	logger.Debug("java invocation", "args", args2)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open stdout pipe: %w", err)
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open stderr pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start java: %w", err)
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(2)
+
+	go logLines(&wg, logger, slog.LevelInfo, stdout)
+	go logLines(&wg, logger, slog.LevelWarn, stderr)
+
+	wg.Wait()
+	cmd.Wait()
+
+	if err := os.RemoveAll(nativesDir); err != nil {
+		return fmt.Errorf("failed to remove natives directory: %w", err)
+	}
+
+	return nil
+}
+
+// logLines forwards each line read from r through logger at the given
+// level, tagged with source=minecraft, until r is exhausted.
+func logLines(wg *sync.WaitGroup, logger *slog.Logger, level slog.Level, r io.Reader) {
+	defer wg.Done()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		logger.Log(context.Background(), level, scanner.Text(), "source", "minecraft")
+	}
+}
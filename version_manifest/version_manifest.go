@@ -0,0 +1,77 @@
+// Package version_manifest fetches and decodes Mojang's version manifest
+// and resolves version ids, including the release/snapshot aliases, to
+// the download location and checksum of that version's JSON.
+package version_manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ManifestURL is the location of Mojang's version manifest.
+const ManifestURL = "https://launchermeta.mojang.com/mc/game/version_manifest_v2.json"
+
+// Latest holds the ids of the current release and snapshot versions.
+type Latest struct {
+	Release  string `json:"release"`
+	Snapshot string `json:"snapshot"`
+}
+
+// Version is a single entry in the manifest.
+type Version struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	URL         string    `json:"url"`
+	Time        time.Time `json:"time"`
+	ReleaseTime time.Time `json:"releaseTime"`
+	SHA1        string    `json:"sha1"`
+}
+
+// Manifest is the decoded version_manifest_v2.json.
+type Manifest struct {
+	Latest   Latest    `json:"latest"`
+	Versions []Version `json:"versions"`
+}
+
+// Fetch downloads and decodes the version manifest from ManifestURL.
+func Fetch() (*Manifest, error) {
+	resp, err := http.Get(ManifestURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch version manifest: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch version manifest: unexpected status %s", resp.Status)
+	}
+
+	m := new(Manifest)
+	if err := json.NewDecoder(resp.Body).Decode(m); err != nil {
+		return nil, fmt.Errorf("failed to decode version manifest: %w", err)
+	}
+
+	return m, nil
+}
+
+// Resolve turns a version id, or one of the aliases "release",
+// "snapshot", "latest-release" and "latest-snapshot", into the matching
+// manifest entry.
+func (m *Manifest) Resolve(id string) (*Version, error) {
+	switch id {
+	case "release", "latest-release":
+		id = m.Latest.Release
+	case "snapshot", "latest-snapshot":
+		id = m.Latest.Snapshot
+	}
+
+	for i, v := range m.Versions {
+		if v.ID == id {
+			return &m.Versions[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown version: %s", id)
+}